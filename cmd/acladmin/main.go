@@ -0,0 +1,55 @@
+// +build !oss
+
+/*
+ * Copyright 2018 Dgraph Labs, Inc. All rights reserved.
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+// Command acladmin serves edgraph's read-only ACL admin gRPC surface
+// (MaterializeView, EffectiveAccess, AuthorizePredicateDryRun) on its own
+// listener, so operators can inspect effective ACL state and reproduce
+// denials without enabling trace logging on the main alpha server.
+//
+// The admin cache it reads is in-memory and populated only by the alpha's
+// own group-update subscription loop, so this binary is only useful when
+// compiled into (or otherwise run in-process with) that alpha server — run
+// standalone, every RPC fails with Unavailable until something in this
+// process calls aclCache.update.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/dgraph-io/dgraph/edgraph"
+	"github.com/golang/glog"
+	"google.golang.org/grpc"
+)
+
+var port = flag.Int("port", 9081, "Port to serve the ACL admin gRPC surface on.")
+
+func main() {
+	flag.Parse()
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	s := grpc.NewServer()
+	edgraph.RegisterAclAdminServer(s)
+
+	glog.Infof("ACL admin server listening on %s", lis.Addr())
+	if err := s.Serve(lis); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
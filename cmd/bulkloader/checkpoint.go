@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const manifestFileName = "manifest.json"
+
+// inputFileInfo records enough metadata about a single RDF input to detect
+// whether it has changed between bulk loader runs.
+type inputFileInfo struct {
+	Path    string
+	Size    int64
+	ModTime int64
+}
+
+// manifest is the on-disk record of how far a bulk load has progressed, so
+// that --resume can pick up from the last fully-consumed line of each input
+// file and the already-sealed posting shards, instead of starting over.
+type manifest struct {
+	// IntegrityHash covers the schema file and the sorted input file
+	// metadata; resuming against a manifest whose hash doesn't match the
+	// current --rdf_files/--schema is refused.
+	IntegrityHash string
+	Inputs        []inputFileInfo
+	// FileOffsets is, per input file, the byte offset of the last fully
+	// consumed line.
+	FileOffsets map[string]int64
+	// CompletedShards lists sealed posting shard files (shard-N.complete)
+	// that the reduce stage can consume directly without re-mapping.
+	CompletedShards []string
+}
+
+func manifestPath(tmpDir string) string {
+	return filepath.Join(tmpDir, manifestFileName)
+}
+
+func statInputs(files []string) []inputFileInfo {
+	infos := make([]inputFileInfo, len(files))
+	for i, f := range files {
+		fi, err := os.Stat(f)
+		x.Checkf(err, "Could not stat input file %q.", f)
+		infos[i] = inputFileInfo{Path: f, Size: fi.Size(), ModTime: fi.ModTime().UnixNano()}
+	}
+	return infos
+}
+
+// computeIntegrityHash hashes the schema file together with the sorted
+// input file sizes/mtimes, so that resuming against a changed input is
+// refused rather than silently corrupting the output.
+func computeIntegrityHash(schemaFile string, inputs []inputFileInfo) string {
+	sorted := make([]inputFileInfo, len(inputs))
+	copy(sorted, inputs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	schemaBytes, err := ioutil.ReadFile(schemaFile)
+	x.Checkf(err, "Could not read schema file %q.", schemaFile)
+
+	h := sha256.New()
+	h.Write(schemaBytes)
+	for _, in := range sorted {
+		fmt.Fprintf(h, "%s:%d:%d\n", in.Path, in.Size, in.ModTime)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadManifest reads a checkpoint manifest from tmpDir, returning nil (and
+// no error) if none exists yet.
+func loadManifest(tmpDir string) *manifest {
+	b, err := ioutil.ReadFile(manifestPath(tmpDir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	x.Checkf(err, "Could not read checkpoint manifest.")
+
+	var m manifest
+	x.Checkf(json.Unmarshal(b, &m), "Could not parse checkpoint manifest.")
+	return &m
+}
+
+// writeManifest persists m to tmpDir via a write-then-rename so a crash
+// mid-write can never leave a corrupt manifest behind.
+func writeManifest(tmpDir string, m *manifest) {
+	b, err := json.Marshal(m)
+	x.Check(err)
+
+	tmp := manifestPath(tmpDir) + ".tmp"
+	x.Check(ioutil.WriteFile(tmp, b, 0644))
+	x.Check(os.Rename(tmp, manifestPath(tmpDir)))
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write %q: %v", path, err)
+	}
+	return path
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "bulkloader_checkpoint_test_")
+	if err != nil {
+		t.Fatalf("could not create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if m := loadManifest(tmpDir); m != nil {
+		t.Fatalf("expected no manifest before one is written, got %+v", m)
+	}
+
+	want := &manifest{
+		IntegrityHash:   "deadbeef",
+		FileOffsets:     map[string]int64{"a.rdf": 42},
+		CompletedShards: []string{"shard-0.complete"},
+	}
+	writeManifest(tmpDir, want)
+
+	got := loadManifest(tmpDir)
+	if got == nil {
+		t.Fatal("expected a manifest after writing one")
+	}
+	if got.IntegrityHash != want.IntegrityHash {
+		t.Errorf("IntegrityHash = %q, want %q", got.IntegrityHash, want.IntegrityHash)
+	}
+	if got.FileOffsets["a.rdf"] != 42 {
+		t.Errorf("FileOffsets[a.rdf] = %d, want 42", got.FileOffsets["a.rdf"])
+	}
+	if len(got.CompletedShards) != 1 || got.CompletedShards[0] != "shard-0.complete" {
+		t.Errorf("CompletedShards = %v, want [shard-0.complete]", got.CompletedShards)
+	}
+}
+
+func TestIntegrityHashChangesWithInput(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "bulkloader_checkpoint_test_")
+	if err != nil {
+		t.Fatalf("could not create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	schemaFile := writeTempFile(t, tmpDir, "schema.txt", "name: string .")
+	rdfFile := writeTempFile(t, tmpDir, "a.rdf", "<a> <name> \"a\" .\n")
+
+	before := computeIntegrityHash(schemaFile, statInputs([]string{rdfFile}))
+
+	// Appending to the input must change the hash, since resuming against a
+	// changed input should be refused.
+	f, err := os.OpenFile(rdfFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("could not append to rdf file: %v", err)
+	}
+	if _, err := f.WriteString("<a> <name> \"b\" .\n"); err != nil {
+		t.Fatalf("could not append to rdf file: %v", err)
+	}
+	f.Close()
+
+	after := computeIntegrityHash(schemaFile, statInputs([]string{rdfFile}))
+	if before == after {
+		t.Fatalf("expected integrity hash to change once the input file changed, got %q both times", before)
+	}
+}
+
+// TestResumeSkipsSealedShardsAndCompletedFiles simulates a crash after the
+// map stage has sealed one shard and fully consumed one of two input files:
+// a resumed run must not re-map that file, and the reduce stage must still
+// see the shard sealed by the earlier run.
+func TestResumeSkipsSealedShardsAndCompletedFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "bulkloader_checkpoint_test_")
+	if err != nil {
+		t.Fatalf("could not create tmp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	doneFile := writeTempFile(t, tmpDir, "done.rdf", "<a> <name> \"a\" .\n")
+	pendingFile := writeTempFile(t, tmpDir, "pending.rdf", "<b> <name> \"b\" .\n")
+	doneFi, err := os.Stat(doneFile)
+	if err != nil {
+		t.Fatalf("could not stat done file: %v", err)
+	}
+
+	rawShard := writeTempFile(t, tmpDir, "shard-0", "posting-bytes")
+
+	ld := &loader{
+		state: &state{opt: options{tmpDir: tmpDir}},
+		manifest: &manifest{
+			FileOffsets: map[string]int64{doneFile: doneFi.Size()},
+		},
+	}
+
+	// sealShards is what mapStage calls once writeMapOutput hands back a
+	// freshly produced shard; it must record the sealed name in the
+	// manifest so a later run's reduce stage can find it.
+	ld.sealShards([]string{rawShard})
+
+	if len(ld.mapOutput) != 1 || ld.mapOutput[0] != rawShard+".complete" {
+		t.Fatalf("mapOutput = %v, want [%s]", ld.mapOutput, rawShard+".complete")
+	}
+	if _, err := os.Stat(rawShard + ".complete"); err != nil {
+		t.Fatalf("expected sealed shard on disk: %v", err)
+	}
+
+	persisted := loadManifest(tmpDir)
+	if persisted == nil || len(persisted.CompletedShards) != 1 {
+		t.Fatalf("expected sealed shard persisted in manifest, got %+v", persisted)
+	}
+
+	// Simulate newLoader's resume-time filtering logic directly: only files
+	// whose recorded offset has not reached their current size are pending.
+	rdfFiles := []string{doneFile, pendingFile}
+	var pendingFiles []string
+	for _, f := range rdfFiles {
+		fi, err := os.Stat(f)
+		if err != nil {
+			t.Fatalf("could not stat %q: %v", f, err)
+		}
+		if offset, done := ld.manifest.FileOffsets[f]; done && offset >= fi.Size() {
+			continue
+		}
+		pendingFiles = append(pendingFiles, f)
+	}
+	if len(pendingFiles) != 1 || pendingFiles[0] != pendingFile {
+		t.Fatalf("pendingFiles = %v, want [%s]", pendingFiles, pendingFile)
+	}
+}
@@ -3,23 +3,33 @@ package main
 import (
 	"bufio"
 	"compress/gzip"
+	"io"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
 	"github.com/dgraph-io/dgraph/x"
+	"github.com/golang/glog"
 )
 
 type options struct {
-	rdfFile       string
+	rdfFiles      []string
 	schemaFile    string
 	badgerDir     string
 	tmpDir        string
 	numGoroutines int
+	// readerConcurrency is the number of goroutines reading rdfFiles in
+	// parallel; it defaults to min(len(rdfFiles), numGoroutines) when unset.
+	readerConcurrency int
+	// resume picks up a prior map stage from its checkpoint manifest in
+	// tmpDir, if one is present and its integrity hash still matches
+	// rdfFiles and schemaFile. restart instead wipes tmpDir and starts
+	// fresh, even if a manifest is present; the two are mutually exclusive.
+	resume  bool
+	restart bool
 }
 
 type state struct {
@@ -35,9 +45,23 @@ type loader struct {
 	*state
 	mappers   []*mapper
 	mapOutput []string
+
+	// manifest is the checkpoint being extended by this run; nil if
+	// checkpointing was not requested. resuming is true when manifest was
+	// loaded from a prior, still-valid run rather than created fresh.
+	// manifestMu guards concurrent updates from the per-file reader
+	// goroutines in mapStage.
+	manifest   *manifest
+	manifestMu sync.Mutex
+	resuming   bool
+	// success is set once reduceStage has finished; cleanup() only removes
+	// tmpDir when this is true, so a crash leaves the checkpoint behind.
+	success bool
 }
 
 func newLoader(opt options) *loader {
+	x.AssertTruef(!(opt.resume && opt.restart), "--resume and --restart are mutually exclusive")
+
 	schemaBuf, err := ioutil.ReadFile(opt.schemaFile)
 	x.Checkf(err, "Could not load schema.")
 	initialSchema, err := schema.Parse(string(schemaBuf))
@@ -58,6 +82,21 @@ func newLoader(opt options) *loader {
 	for i := 0; i < opt.numGoroutines; i++ {
 		ld.mappers[i] = &mapper{state: st}
 	}
+
+	hash := computeIntegrityHash(opt.schemaFile, statInputs(opt.rdfFiles))
+	if opt.restart {
+		x.Check(os.RemoveAll(opt.tmpDir))
+		x.Check(os.MkdirAll(opt.tmpDir, 0755))
+	} else if m := loadManifest(opt.tmpDir); m != nil && m.IntegrityHash == hash {
+		ld.manifest = m
+		ld.resuming = true
+	} else if m != nil {
+		glog.Errorf("Ignoring checkpoint in %q: inputs have changed since it was written.",
+			opt.tmpDir)
+	}
+	if ld.manifest == nil {
+		ld.manifest = &manifest{IntegrityHash: hash, FileOffsets: make(map[string]int64)}
+	}
 	return ld
 }
 
@@ -71,14 +110,10 @@ func (ld *loader) mapStage() {
 	x.Check(err)
 
 	go func() {
-		ld.mapOutput = writeMapOutput(tmpPostingsDir, ld.postingsCh, ld.prog)
+		ld.sealShards(writeMapOutput(tmpPostingsDir, ld.postingsCh, ld.prog))
 		postingWriterWg.Done()
 	}()
 
-	f, err := os.Open(ld.opt.rdfFile)
-	x.Checkf(err, "Could not read RDF file.")
-	defer f.Close()
-
 	var mapperWg sync.WaitGroup
 	mapperWg.Add(len(ld.mappers))
 	for _, m := range ld.mappers {
@@ -88,19 +123,45 @@ func (ld *loader) mapStage() {
 		}(m)
 	}
 
-	var sc *bufio.Scanner
-	if !strings.HasSuffix(ld.opt.rdfFile, ".gz") {
-		sc = bufio.NewScanner(f)
-	} else {
-		gzr, err := gzip.NewReader(f)
-		x.Checkf(err, "Could not create gzip reader for RDF file.")
-		sc = bufio.NewScanner(gzr)
+	pendingFiles := ld.opt.rdfFiles
+	if ld.resuming {
+		glog.Infof("Resuming map stage from checkpoint in %q.", ld.opt.tmpDir)
+		pendingFiles = nil
+		for _, file := range ld.opt.rdfFiles {
+			fi, err := os.Stat(file)
+			x.Checkf(err, "Could not stat RDF file %q.", file)
+			if offset, done := ld.manifest.FileOffsets[file]; done && offset >= fi.Size() {
+				continue
+			}
+			pendingFiles = append(pendingFiles, file)
+		}
 	}
 
-	for i := 0; sc.Scan(); i++ {
-		ld.rdfCh <- sc.Text()
+	readerConcurrency := ld.opt.readerConcurrency
+	if readerConcurrency == 0 {
+		readerConcurrency = ld.opt.numGoroutines
+	}
+	if readerConcurrency > len(pendingFiles) {
+		readerConcurrency = len(pendingFiles)
+	}
+
+	fileCh := make(chan string, len(pendingFiles))
+	for _, file := range pendingFiles {
+		fileCh <- file
+	}
+	close(fileCh)
+
+	var readerWg sync.WaitGroup
+	readerWg.Add(readerConcurrency)
+	for i := 0; i < readerConcurrency; i++ {
+		go func() {
+			defer readerWg.Done()
+			for file := range fileCh {
+				ld.readRdfFile(file)
+			}
+		}()
 	}
-	x.Check(sc.Err())
+	readerWg.Wait()
 
 	close(ld.rdfCh)
 	mapperWg.Wait()
@@ -108,6 +169,118 @@ func (ld *loader) mapStage() {
 	postingWriterWg.Wait()
 }
 
+// checkpointInterval is how many lines readRdfFile consumes between manifest
+// flushes; resuming after a crash loses at most this many lines of progress.
+const checkpointInterval = 100000
+
+// readRdfFile streams a single RDF input (transparently gunzipping it if its
+// name ends in ".gz") into the shared rdfCh, resuming from the manifest's
+// recorded offset for this file if one was loaded. Several of these run
+// concurrently so that decompressing one large gzipped shard doesn't
+// bottleneck the whole map stage.
+//
+// Uncompressed files resume via a seek; gzip streams can't be seeked into
+// cheaply, so a resumed gzip file is re-decompressed from the start and its
+// already-consumed lines are discarded without being re-sent on rdfCh.
+func (ld *loader) readRdfFile(file string) {
+	f, err := os.Open(file)
+	x.Checkf(err, "Could not read RDF file %q.", file)
+	defer f.Close()
+
+	fi, err := f.Stat()
+	x.Checkf(err, "Could not stat RDF file %q.", file)
+	ld.prog.setFileSize(file, fi.Size())
+
+	startOffset := ld.manifest.FileOffsets[file]
+	isGzip := strings.HasSuffix(file, ".gz")
+	if startOffset > 0 && !isGzip {
+		_, err := f.Seek(startOffset, io.SeekStart)
+		x.Checkf(err, "Could not resume RDF file %q at offset %d.", file, startOffset)
+	}
+
+	// cr counts raw bytes pulled off disk (the compressed size for a .gz
+	// file), the same units as the on-disk size passed to setFileSize
+	// above, so aggregate progress across mixed plain/gzipped inputs stays
+	// meaningful.
+	cr := &countingReader{r: f, onRead: func(n int64) { ld.prog.addBytesRead(file, n) }}
+	var r io.Reader = cr
+	if isGzip {
+		gzr, err := gzip.NewReader(cr)
+		x.Checkf(err, "Could not create gzip reader for RDF file %q.", file)
+		r = gzr
+	}
+
+	offset := startOffset
+	if isGzip {
+		offset = 0
+	}
+	sc := bufio.NewScanner(r)
+	var linesSinceCheckpoint int
+	for sc.Scan() {
+		n := int64(len(sc.Bytes()) + 1)
+		if isGzip && offset < startOffset {
+			offset += n
+			continue
+		}
+		ld.rdfCh <- sc.Text()
+		offset += n
+
+		linesSinceCheckpoint++
+		if linesSinceCheckpoint >= checkpointInterval {
+			ld.checkpoint(file, offset)
+			linesSinceCheckpoint = 0
+		}
+	}
+	x.Checkf(sc.Err(), "Error scanning RDF file %q.", file)
+	ld.checkpoint(file, offset)
+}
+
+// countingReader wraps an io.Reader and reports every successful read to
+// onRead, so callers can track aggregate bytes consumed without threading a
+// counter through intermediate readers (e.g. a gzip.Reader wrapping it).
+type countingReader struct {
+	r      io.Reader
+	onRead func(n int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.onRead(int64(n))
+	}
+	return n, err
+}
+
+// checkpoint records that file has been consumed up to offset and persists
+// the manifest, so a crash resumes from here rather than from scratch.
+func (ld *loader) checkpoint(file string, offset int64) {
+	ld.manifestMu.Lock()
+	defer ld.manifestMu.Unlock()
+	ld.manifest.FileOffsets[file] = offset
+	writeManifest(ld.opt.tmpDir, ld.manifest)
+}
+
+// sealShards atomically renames each freshly written posting shard to
+// "<name>.complete", records it in the manifest alongside any shards sealed
+// by a prior, resumed run, and persists the manifest before exposing the
+// combined list as ld.mapOutput. Sealing happens only after writeMapOutput
+// has fully flushed a shard, so a shard only ever appears in the manifest
+// once it is safe for the reduce stage to consume without re-mapping.
+func (ld *loader) sealShards(freshShards []string) {
+	sealed := make([]string, len(freshShards))
+	for i, shard := range freshShards {
+		sealedPath := shard + ".complete"
+		x.Check(os.Rename(shard, sealedPath))
+		sealed[i] = sealedPath
+	}
+
+	ld.manifestMu.Lock()
+	defer ld.manifestMu.Unlock()
+	ld.manifest.CompletedShards = append(ld.manifest.CompletedShards, sealed...)
+	writeManifest(ld.opt.tmpDir, ld.manifest)
+	ld.mapOutput = ld.manifest.CompletedShards
+}
+
 func (ld *loader) reduceStage() {
 	// Read from map stage.
 	shuffleInputChs := make([]chan *protos.FlatPosting, len(ld.mapOutput))
@@ -135,11 +308,18 @@ func (ld *loader) reduceStage() {
 	reduceWg.Wait()
 
 	ld.prog.endSummary()
+	ld.success = true
 }
 
+// cleanup removes the tmp directory (and with it the checkpoint manifest and
+// every posting shard directory nested under it, across however many
+// resumed runs contributed to ld.mapOutput) once the whole load has
+// succeeded. It deliberately does nothing if reduceStage never finished, so
+// a crash leaves the checkpoint in place for a subsequent --resume instead
+// of corrupting or discarding it.
 func (ld *loader) cleanup() {
-	if len(ld.mapOutput) > 0 {
-		dir := filepath.Dir(ld.mapOutput[0])
-		x.Check(os.RemoveAll(dir))
+	if !ld.success {
+		return
 	}
+	x.Check(os.RemoveAll(ld.opt.tmpDir))
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// progress tracks the bulk loader's throughput across all of its RDF
+// inputs so mapStage can report aggregate progress and an ETA, rather than
+// per-file counters that say nothing about the overall load.
+type progress struct {
+	start time.Time
+
+	mu        sync.Mutex
+	fileSizes map[string]int64
+	bytesRead map[string]int64
+}
+
+func newProgress() *progress {
+	return &progress{
+		start:     time.Now(),
+		fileSizes: make(map[string]int64),
+		bytesRead: make(map[string]int64),
+	}
+}
+
+// setFileSize records file's total size, so its contribution to totalBytes
+// is known even before any of it has been read.
+func (p *progress) setFileSize(file string, size int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fileSizes[file] = size
+}
+
+// addBytesRead accumulates n more bytes consumed from file, so the reported
+// ETA reflects bytes remaining across all inputs rather than just the one
+// file currently being scanned.
+func (p *progress) addBytesRead(file string, n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytesRead[file] += n
+}
+
+// totalBytes returns the combined size of every file progress knows about
+// and how many of those bytes have been read so far.
+func (p *progress) totalBytes() (total, read int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for file, size := range p.fileSizes {
+		total += size
+		read += p.bytesRead[file]
+	}
+	return total, read
+}
+
+// report periodically logs aggregate progress until the process exits; it
+// is started as a goroutine from mapStage.
+func (p *progress) report() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.log()
+	}
+}
+
+// endSummary logs one final progress line once the map stage has finished.
+func (p *progress) endSummary() {
+	p.log()
+}
+
+func (p *progress) log() {
+	total, read := p.totalBytes()
+	var pct float64
+	if total > 0 {
+		pct = float64(read) / float64(total) * 100
+	}
+	glog.Infof("[MAP] %s elapsed, %d/%d bytes read (%.1f%%)",
+		time.Since(p.start).Round(time.Second), read, total, pct)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var opt options
+
+// cmd is the bulkloader's cobra command; Execute() is called from main().
+var cmd = &cobra.Command{
+	Use:   "dgraph-bulk-loader",
+	Short: "Bulk loader for Dgraph",
+	Run: func(c *cobra.Command, args []string) {
+		run()
+	},
+}
+
+func init() {
+	flag := cmd.Flags()
+	flag.StringVarP(&rdfFilesFlag, "rdf_files", "r", "",
+		"Comma separated list of RDF files (or .gz) to load.")
+	flag.StringVarP(&opt.schemaFile, "schema", "s", "", "Location of schema file.")
+	flag.StringVar(&opt.badgerDir, "out", "out", "Location to write the final dgraph data directories.")
+	flag.StringVar(&opt.tmpDir, "tmp", "tmp", "Temp directory used to use for on-disk scratch space.")
+	flag.IntVarP(&opt.numGoroutines, "num_go_routines", "j", 4,
+		"Number of worker goroutines to use.")
+	flag.IntVar(&opt.readerConcurrency, "reader_concurrency", 0,
+		"Number of goroutines reading RDF files in parallel (0 = same as num_go_routines).")
+	flag.BoolVar(&opt.resume, "resume", false,
+		"Resume a previous bulk load from the checkpoint in --tmp, if one is present and still valid.")
+	flag.BoolVar(&opt.restart, "restart", false,
+		"Discard any checkpoint in --tmp and start the bulk load from scratch.")
+}
+
+// rdfFilesFlag backs --rdf_files as a comma separated string; it is split
+// into opt.rdfFiles in run() once flag parsing has completed.
+var rdfFilesFlag string
+
+// run is cmd's Run func; by the time it fires, cobra has already parsed
+// cmd.Flags() and populated opt, so there is nothing left to parse here.
+func run() {
+	if rdfFilesFlag != "" {
+		opt.rdfFiles = strings.Split(rdfFilesFlag, ",")
+	}
+
+	ld := newLoader(opt)
+	ld.mapStage()
+	ld.reduceStage()
+	ld.cleanup()
+}
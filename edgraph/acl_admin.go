@@ -0,0 +1,155 @@
+// +build !oss
+
+/*
+ * Copyright 2018 Dgraph Labs, Inc. All rights reserved.
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+package edgraph
+
+import (
+	"github.com/dgraph-io/dgraph/ee/acl"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// ruleKindRegex labels a MaterializedRule built from the regex index, to go
+// alongside the ruleKind* constants used for Rule.Kind itself.
+const ruleKindRegex = "regex"
+
+// MaterializedGroupPerm is the allow/deny bits a single group holds for a
+// MaterializedRule's pattern.
+type MaterializedGroupPerm struct {
+	Allow int32
+	Deny  int32
+}
+
+// MaterializedRule is one entry of the fully materialised ACL view returned
+// by MaterializeACLView: a single predicate pattern together with the
+// allow/deny bits every group holds for it.
+type MaterializedRule struct {
+	Kind    string
+	Pattern string
+	Groups  map[string]MaterializedGroupPerm
+}
+
+func materializeGroupPerms(groupPerms map[string]int32) map[string]MaterializedGroupPerm {
+	out := make(map[string]MaterializedGroupPerm, len(groupPerms))
+	for group, perm := range groupPerms {
+		allow, deny := splitPerm(perm)
+		out[group] = MaterializedGroupPerm{Allow: allow, Deny: deny}
+	}
+	return out
+}
+
+// MaterializeACLView walks predPerms, predPrefixRules, predWildcardRules and
+// predRegexRules under the read lock and returns the fully materialised
+// view: for each predicate pattern, the set of groups and their allow/deny
+// bits. This is what the admin gRPC surface marshals into a response for
+// CLI tooling and policy-diff tests, since today's authorizePredicate only
+// answers the single-predicate question and gives no way to see the whole
+// effective policy at once.
+func (cache *aclCache) MaterializeACLView() []*MaterializedRule {
+	aclCachePtr.RLock()
+	predPerms := aclCachePtr.predPerms
+	predPrefixRules := aclCachePtr.predPrefixRules
+	predWildcardRules := aclCachePtr.predWildcardRules
+	predRegexRules := aclCachePtr.predRegexRules
+	aclCachePtr.RUnlock()
+
+	var view []*MaterializedRule
+	for predicate, groupPerms := range predPerms {
+		view = append(view, &MaterializedRule{
+			Kind:    ruleKindExact,
+			Pattern: predicate,
+			Groups:  materializeGroupPerms(groupPerms),
+		})
+	}
+	predPrefixRules.walk(func(rule *predPrefixRule) {
+		view = append(view, &MaterializedRule{
+			Kind:    ruleKindPrefix,
+			Pattern: rule.prefix + "*",
+			Groups:  materializeGroupPerms(rule.groupPerms),
+		})
+	})
+	for _, rule := range predWildcardRules {
+		view = append(view, &MaterializedRule{
+			Kind:    ruleKindWildcard,
+			Pattern: rule.template,
+			Groups:  materializeGroupPerms(rule.groupPerms),
+		})
+	}
+	for _, rule := range predRegexRules {
+		view = append(view, &MaterializedRule{
+			Kind:    ruleKindRegex,
+			Pattern: rule.source,
+			Groups:  materializeGroupPerms(rule.groupPerms),
+		})
+	}
+	return view
+}
+
+// EffectiveAccess answers "given a user in groups, what access do they have
+// on each known predicate pattern", by combining the allow/deny bits across
+// every rule for those groups. A pattern is omitted once its allow bits are
+// fully cancelled out by a deny, so the result only lists real access.
+func (cache *aclCache) EffectiveAccess(groups []string) map[string]int32 {
+	effective := make(map[string]int32)
+	for _, rule := range cache.MaterializeACLView() {
+		var allow, deny int32
+		for _, group := range groups {
+			if perm, found := rule.Groups[group]; found {
+				allow |= perm.Allow
+				deny |= perm.Deny
+			}
+		}
+		if remaining := allow &^ deny; remaining != 0 {
+			effective[rule.Pattern] = remaining
+		}
+	}
+	return effective
+}
+
+// AuthorizePredicateDryRun reproduces the decision authorizePredicate would
+// make for (groups, predicate, operation), but never errors and always
+// returns every rule that matched, whether or not it ended up granting
+// access. This lets an operator reproduce a denial and see exactly which
+// rules were in play, without enabling trace logging.
+func (cache *aclCache) AuthorizePredicateDryRun(groups []string, predicate string,
+	operation *acl.Operation) (allowed bool, matches []Grant) {
+	if x.IsAclPredicate(predicate) {
+		return false, nil
+	}
+
+	rules := aclCachePtr.matchingRules(predicate)
+
+	var allow, deny int32
+	for _, rule := range rules {
+		for _, group := range groups {
+			perm, found := rule.groupPerms[group]
+			if !found {
+				continue
+			}
+			matches = append(matches, Grant{GroupID: group, Pattern: rule.pattern})
+			groupAllow, groupDeny := splitPerm(perm)
+			allow |= groupAllow
+			deny |= groupDeny
+		}
+	}
+
+	switch {
+	case deny&operation.Code != 0:
+		return false, matches
+	case allow&operation.Code == operation.Code:
+		return true, matches
+	case len(rules) > 0:
+		return false, matches
+	default:
+		// no rule has been defined that can match the predicate; fail open
+		return true, matches
+	}
+}
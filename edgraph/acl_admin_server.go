@@ -0,0 +1,87 @@
+// +build !oss
+
+/*
+ * Copyright 2018 Dgraph Labs, Inc. All rights reserved.
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+package edgraph
+
+import (
+	"context"
+
+	"github.com/dgraph-io/dgraph/ee/acl"
+	"github.com/dgraph-io/dgraph/protos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// aclAdminServer implements protos.AclAdminServer against the package-level
+// aclCachePtr, so operators get a real RPC surface for the read-only
+// MaterializeACLView/EffectiveAccess/AuthorizePredicateDryRun helpers
+// instead of only being able to reach them from Go code in this package.
+type aclAdminServer struct{}
+
+var _ protos.AclAdminServer = (*aclAdminServer)(nil)
+
+// RegisterAclAdminServer registers the ACL admin RPC surface on s, for the
+// dgraph server binary to call alongside its other service registrations.
+func RegisterAclAdminServer(s *grpc.Server) {
+	protos.RegisterAclAdminServer(s, &aclAdminServer{})
+}
+
+// errCacheUninitialized is returned by every RPC below instead of silently
+// reporting an empty/fail-open result when aclCachePtr has never been
+// populated — e.g. this server is running in a process that never runs the
+// group-update subscription loop, such as the standalone acladmin binary
+// started against an alpha it isn't actually attached to.
+var errCacheUninitialized = status.Error(codes.Unavailable,
+	"acl cache has not been populated yet; this server must run in the same "+
+		"process as the alpha that maintains it")
+
+func (s *aclAdminServer) MaterializeView(ctx context.Context,
+	req *protos.AclViewRequest) (*protos.AclView, error) {
+	if !aclCachePtr.Initialized() {
+		return nil, errCacheUninitialized
+	}
+	rules := aclCachePtr.MaterializeACLView()
+	resp := &protos.AclView{Rules: make([]*protos.AclRule, len(rules))}
+	for i, rule := range rules {
+		groups := make(map[string]*protos.AclGroupPerm, len(rule.Groups))
+		for group, perm := range rule.Groups {
+			groups[group] = &protos.AclGroupPerm{Allow: perm.Allow, Deny: perm.Deny}
+		}
+		resp.Rules[i] = &protos.AclRule{Kind: rule.Kind, Pattern: rule.Pattern, Groups: groups}
+	}
+	return resp, nil
+}
+
+func (s *aclAdminServer) EffectiveAccess(ctx context.Context,
+	req *protos.AclEffectiveAccessRequest) (*protos.AclEffectiveAccessResponse, error) {
+	if !aclCachePtr.Initialized() {
+		return nil, errCacheUninitialized
+	}
+	access := aclCachePtr.EffectiveAccess(req.Groups)
+	return &protos.AclEffectiveAccessResponse{Access: access}, nil
+}
+
+func (s *aclAdminServer) AuthorizePredicateDryRun(ctx context.Context,
+	req *protos.AclDryRunRequest) (*protos.AclDryRunResponse, error) {
+	if !aclCachePtr.Initialized() {
+		return nil, errCacheUninitialized
+	}
+	operation := &acl.Operation{Code: req.OperationCode, Name: req.OperationName}
+	allowed, matches := aclCachePtr.AuthorizePredicateDryRun(req.Groups, req.Predicate, operation)
+
+	resp := &protos.AclDryRunResponse{Allowed: allowed, Matches: make([]*protos.AclGrant, len(matches))}
+	for i, m := range matches {
+		resp.Matches[i] = &protos.AclGrant{GroupId: m.GroupID, Pattern: m.Pattern}
+	}
+	return resp, nil
+}
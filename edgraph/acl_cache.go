@@ -14,7 +14,9 @@ package edgraph
 
 import (
 	"encoding/json"
+	"expvar"
 	"regexp"
+	"strings"
 	"sync"
 
 	"github.com/dgraph-io/dgraph/ee/acl"
@@ -23,21 +25,108 @@ import (
 	"github.com/pkg/errors"
 )
 
+var (
+	regexPoolHits   = expvar.NewInt("dgraph_acl_regex_pool_hits_total")
+	regexPoolMisses = expvar.NewInt("dgraph_acl_regex_pool_misses_total")
+)
+
+// regexPool interns compiled regexes across update() cycles, keyed by their
+// source string, so that memory usage scales with the number of distinct
+// patterns rather than with (groups x patterns). The pool is rebuilt after
+// every update() call to drop patterns that are no longer referenced by any
+// group.
+var regexPool sync.Map // map[string]*regexp.Regexp
+
+// internRegex returns the shared *regexp.Regexp for pattern, compiling and
+// caching it on a miss.
+func internRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexPool.Load(pattern); ok {
+		regexPoolHits.Add(1)
+		return cached.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexPoolMisses.Add(1)
+	actual, _ := regexPool.LoadOrStore(pattern, compiled)
+	return actual.(*regexp.Regexp), nil
+}
+
+// Rule.Kind values understood by aclCache.update(). The zero value
+// (ruleKindExact) keeps the historical exact-predicate behaviour.
+const (
+	ruleKindExact    = ""
+	ruleKindPrefix   = "prefix"
+	ruleKindWildcard = "wildcard"
+)
+
+// wildcardSegment is "+" in rules such as "schema/+/name", matching exactly
+// one path segment.
+const wildcardSegment = "+"
+
 type predRegexRule struct {
+	source     string
 	predRegex  *regexp.Regexp
 	groupPerms map[string]int32
 }
 
+// predPrefixRule is the value stored in aclCache.predPrefixRules; it keeps
+// the original prefix around so that a successful LongestPrefix lookup can
+// still be attributed back to the rule that produced it.
+type predPrefixRule struct {
+	prefix     string
+	groupPerms map[string]int32
+}
+
+// predWildcardRule matches predicates split on "/" against a template such
+// as "schema/+/name", where "+" matches exactly one segment.
+type predWildcardRule struct {
+	template   string
+	segments   []string
+	groupPerms map[string]int32
+}
+
+func (rule *predWildcardRule) match(predicate string) bool {
+	predSegments := strings.Split(predicate, "/")
+	if len(predSegments) != len(rule.segments) {
+		return false
+	}
+	for i, seg := range rule.segments {
+		if seg != wildcardSegment && seg != predSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // aclCache is the cache mapping group names to the corresponding group acls
 type aclCache struct {
 	sync.RWMutex
-	predPerms      map[string]map[string]int32
-	predRegexRules []*predRegexRule
+	predPerms map[string]map[string]int32
+	// predPrefixRules indexes "prefix" rules (e.g. "user.*") by their prefix
+	// (the pattern with the trailing "*" stripped) in a prefixTrie, giving
+	// an O(len(predicate)) longest-prefix lookup instead of a linear regex
+	// scan.
+	predPrefixRules   *prefixTrie
+	predWildcardRules map[string]*predWildcardRule
+	predRegexRules    []*predRegexRule
+	// initialized is set once update has run at least once. A cache that
+	// has never been updated is indistinguishable on its own from one that
+	// was updated with zero rules (both report fail-open access to every
+	// predicate), which is exactly wrong for a process that never runs the
+	// group-update subscription loop (e.g. the standalone acladmin binary):
+	// callers need to tell "no rules configured" apart from "this process
+	// never loaded any ACL state at all".
+	initialized bool
 }
 
 var aclCachePtr = &aclCache{
-	predPerms:      make(map[string]map[string]int32),
-	predRegexRules: make([]*predRegexRule, 0),
+	predPerms:         make(map[string]map[string]int32),
+	predPrefixRules:   newPrefixTrie(),
+	predWildcardRules: make(map[string]*predWildcardRule),
+	predRegexRules:    make([]*predRegexRule, 0),
 }
 
 func (cache *aclCache) update(groups []acl.Group) {
@@ -63,6 +152,13 @@ func (cache *aclCache) update(groups []acl.Group) {
 	// predRegexPerms is a map from a regex string to a predRegexRule, and a predRegexRule
 	// contains a map from a group to a permission
 	predRegexPerms := make(map[string]*predRegexRule)
+	// predPrefixPerms is a map from a prefix (the pattern with its trailing
+	// "*" stripped) to the group permissions that apply to it; it is turned
+	// into a radix tree once all groups have been processed.
+	predPrefixPerms := make(map[string]map[string]int32)
+	// predWildcardPerms is a map from a wildcard template (e.g.
+	// "schema/+/name") to its predWildcardRule.
+	predWildcardPerms := make(map[string]*predWildcardRule)
 	for _, group := range groups {
 		aclBytes := []byte(group.Acls)
 		var acls []acl.Acl
@@ -72,7 +168,29 @@ func (cache *aclCache) update(groups []acl.Group) {
 		}
 
 		for _, acl := range acls {
-			if len(acl.Predicate) > 0 {
+			switch {
+			case acl.Kind == ruleKindPrefix && len(acl.Predicate) > 0:
+				prefix := strings.TrimSuffix(acl.Predicate, "*")
+				if groupPerms, found := predPrefixPerms[prefix]; found {
+					groupPerms[group.GroupID] = acl.Perm
+				} else {
+					groupPerms := make(map[string]int32)
+					groupPerms[group.GroupID] = acl.Perm
+					predPrefixPerms[prefix] = groupPerms
+				}
+			case acl.Kind == ruleKindWildcard && len(acl.Predicate) > 0:
+				if rule, found := predWildcardPerms[acl.Predicate]; found {
+					rule.groupPerms[group.GroupID] = acl.Perm
+				} else {
+					groupPerms := make(map[string]int32)
+					groupPerms[group.GroupID] = acl.Perm
+					predWildcardPerms[acl.Predicate] = &predWildcardRule{
+						template:   acl.Predicate,
+						segments:   strings.Split(acl.Predicate, "/"),
+						groupPerms: groupPerms,
+					}
+				}
+			case len(acl.Predicate) > 0:
 				if groupPerms, found := predPerms[acl.Predicate]; found {
 					groupPerms[group.GroupID] = acl.Perm
 				} else {
@@ -80,11 +198,11 @@ func (cache *aclCache) update(groups []acl.Group) {
 					groupPerms[group.GroupID] = acl.Perm
 					predPerms[acl.Predicate] = groupPerms
 				}
-			} else if len(acl.Regex) > 0 {
+			case len(acl.Regex) > 0:
 				if regexRule, found := predRegexPerms[acl.Regex]; found {
 					regexRule.groupPerms[group.GroupID] = acl.Perm
 				} else {
-					predRegex, err := regexp.Compile(acl.Regex)
+					predRegex, err := internRegex(acl.Regex)
 					if err != nil {
 						glog.Errorf("Unable to compile the predicate regex %v "+
 							"to create an ACL rule", acl.Regex)
@@ -94,6 +212,7 @@ func (cache *aclCache) update(groups []acl.Group) {
 					groupPermsMap := make(map[string]int32)
 					groupPermsMap[group.GroupID] = acl.Perm
 					predRegexPerms[acl.Regex] = &predRegexRule{
+						source:     acl.Regex,
 						predRegex:  predRegex,
 						groupPerms: groupPermsMap,
 					}
@@ -108,61 +227,176 @@ func (cache *aclCache) update(groups []acl.Group) {
 		predRegexRules = append(predRegexRules, predRegexRule)
 	}
 
+	predPrefixRules := newPrefixTrie()
+	for prefix, groupPerms := range predPrefixPerms {
+		predPrefixRules.insert(prefix, &predPrefixRule{prefix: prefix, groupPerms: groupPerms})
+	}
+
 	aclCachePtr.Lock()
 	defer aclCachePtr.Unlock()
 	aclCachePtr.predPerms = predPerms
+	aclCachePtr.predPrefixRules = predPrefixRules
+	aclCachePtr.predWildcardRules = predWildcardPerms
 	aclCachePtr.predRegexRules = predRegexRules
+	aclCachePtr.initialized = true
+
+	// Evict any interned regex no longer referenced by this update cycle's
+	// groups, so the pool doesn't grow unboundedly as patterns churn.
+	regexPool.Range(func(key, _ interface{}) bool {
+		if _, stillUsed := predRegexPerms[key.(string)]; !stillUsed {
+			regexPool.Delete(key)
+		}
+		return true
+	})
 }
 
-func (cache *aclCache) authorizePredicate(groups []string, predicate string,
-	operation *acl.Operation) error {
-	if x.IsAclPredicate(predicate) {
-		return errors.Errorf("only groot is allowed to access the ACL predicate: %s", predicate)
+// denyShift splits the permission integer stored per group into an allow
+// half (the low bits, same encoding as before this change) and a deny half
+// (the high bits): deny always wins over allow, regardless of which group
+// or rule granted it.
+const denyShift = 16
+
+func splitPerm(perm int32) (allow, deny int32) {
+	return perm & 0xffff, (perm >> denyShift) & 0xffff
+}
+
+// Grant records a group and the rule pattern (predicate, prefix, wildcard
+// template or regex) within that group which allowed an operation, mirroring
+// Vault's GrantingPolicies concept so that callers can explain *why* a
+// request succeeded.
+type Grant struct {
+	GroupID string
+	Pattern string
+}
+
+// contribution is one (group, rule) pair that holds allow bits overlapping
+// the operation being checked; it is provisional until computeAccess knows
+// whether the combined decision across every matching rule actually allows
+// the operation.
+type contribution struct {
+	groupID, pattern string
+	groupAllow       int32
+}
+
+// computeAccess ORs together the allow and deny bits every group holds
+// across all of rules, the same way authorizePredicate decides access, and
+// only then decides which Grants explain that decision. A single rule's
+// allow bits need not cover the whole operation by themselves: two rules
+// (or two groups) can each contribute part of operation.Code, so the grant
+// set has to be derived from the final combined allow/deny, not per rule.
+func computeAccess(rules []ruleMatch, groups []string,
+	operation *acl.Operation) (allow, deny int32, grants []Grant) {
+	var contributions []contribution
+	for _, rule := range rules {
+		for _, group := range groups {
+			perm, found := rule.groupPerms[group]
+			if !found {
+				continue
+			}
+			groupAllow, groupDeny := splitPerm(perm)
+			allow |= groupAllow
+			deny |= groupDeny
+			if groupAllow&operation.Code != 0 {
+				contributions = append(contributions, contribution{group, rule.pattern, groupAllow})
+			}
+		}
+	}
+
+	if deny&operation.Code != 0 || allow&operation.Code != operation.Code {
+		return allow, deny, nil
 	}
+	for _, c := range contributions {
+		grants = append(grants, Grant{GroupID: c.groupID, Pattern: c.pattern})
+	}
+	return allow, deny, grants
+}
+
+// ruleMatch is one rule (of any kind) that matched a predicate, along with
+// the pattern that matched it; it is the unit shared by authorization,
+// dry-run debugging, and the admin view below.
+type ruleMatch struct {
+	pattern    string
+	groupPerms map[string]int32
+}
 
+// Initialized reports whether update has populated the cache at least once.
+// Callers that read the cache outside of the process that runs the
+// group-update subscription loop (the admin gRPC surface, when served from
+// a standalone binary) must check this before trusting an empty or
+// fail-open result.
+func (cache *aclCache) Initialized() bool {
 	aclCachePtr.RLock()
-	predPerms, predRegexRules := aclCachePtr.predPerms, aclCachePtr.predRegexRules
+	defer aclCachePtr.RUnlock()
+	return aclCachePtr.initialized
+}
+
+// matchingRules returns every rule across the exact, prefix, wildcard and
+// regex indices that matches predicate, in the same precedence order
+// authorizePredicate checks them in.
+func (cache *aclCache) matchingRules(predicate string) []ruleMatch {
+	aclCachePtr.RLock()
+	predPerms := aclCachePtr.predPerms
+	predPrefixRules := aclCachePtr.predPrefixRules
+	predWildcardRules := aclCachePtr.predWildcardRules
+	predRegexRules := aclCachePtr.predRegexRules
 	aclCachePtr.RUnlock()
 
-	var singlePredMatch bool
+	var matches []ruleMatch
 	if groupPerms, found := predPerms[predicate]; found {
-		singlePredMatch = true
-		if hasRequiredAccess(groupPerms, groups, operation) {
-			return nil
+		matches = append(matches, ruleMatch{pattern: predicate, groupPerms: groupPerms})
+	}
+	if prefixRule, found := predPrefixRules.longestPrefix(predicate); found {
+		matches = append(matches, ruleMatch{pattern: prefixRule.prefix, groupPerms: prefixRule.groupPerms})
+	}
+	for _, predWildcardRule := range predWildcardRules {
+		if predWildcardRule.match(predicate) {
+			matches = append(matches,
+				ruleMatch{pattern: predWildcardRule.template, groupPerms: predWildcardRule.groupPerms})
 		}
 	}
-
-	var predRegexMatch bool
 	for _, predRegexRule := range predRegexRules {
 		if predRegexRule.predRegex.MatchString(predicate) {
-			predRegexMatch = true
-			if hasRequiredAccess(predRegexRule.groupPerms, groups, operation) {
-				return nil
-			}
+			matches = append(matches, ruleMatch{pattern: predRegexRule.source, groupPerms: predRegexRule.groupPerms})
 		}
 	}
+	return matches
+}
+
+func (cache *aclCache) authorizePredicate(groups []string, predicate string,
+	operation *acl.Operation) error {
+	_, err := aclCachePtr.authorizePredicateWithInfo(groups, predicate, operation)
+	return err
+}
+
+// authorizePredicateWithInfo behaves like authorizePredicate, but on success
+// also returns the Grants (group + rule pattern pairs) that allowed the
+// operation, for use in audit logs. groot still short-circuits this check
+// entirely at the caller and never reaches here.
+func (cache *aclCache) authorizePredicateWithInfo(groups []string, predicate string,
+	operation *acl.Operation) ([]Grant, error) {
+	if x.IsAclPredicate(predicate) {
+		return nil, errors.Errorf("only groot is allowed to access the ACL predicate: %s", predicate)
+	}
 
-	if singlePredMatch || predRegexMatch {
+	rules := aclCachePtr.matchingRules(predicate)
+	allow, deny, grants := computeAccess(rules, groups, operation)
+
+	if deny&operation.Code != 0 {
+		return nil, errors.Errorf("unauthorized to do %s on predicate %s",
+			operation.Name, predicate)
+	}
+	if allow&operation.Code == operation.Code {
+		return grants, nil
+	}
+
+	if len(rules) > 0 {
 		// there is an ACL rule defined that can match the predicate
 		// and the operation has not been allowed
-		return errors.Errorf("unauthorized to do %s on predicate %s",
+		return nil, errors.Errorf("unauthorized to do %s on predicate %s",
 			operation.Name, predicate)
 	}
 
 	// no rule has been defined that can match the predicate
 	// by default we follow the fail open approach and allow the operation
-	return nil
-}
-
-// hasRequiredAccess checks if any group in the passed in groups is allowed to perform the operation
-// according to the acl rules stored in groupPerms
-func hasRequiredAccess(groupPerms map[string]int32, groups []string,
-	operation *acl.Operation) bool {
-	for _, group := range groups {
-		groupPerm, found := groupPerms[group]
-		if found && (groupPerm&operation.Code != 0) {
-			return true
-		}
-	}
-	return false
+	return nil, nil
 }
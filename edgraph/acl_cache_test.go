@@ -0,0 +1,69 @@
+// +build !oss
+
+package edgraph
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/dgraph/ee/acl"
+)
+
+const (
+	read  int32 = 1 << 0
+	write int32 = 1 << 1
+)
+
+func TestComputeAccessCombinesBitsAcrossRules(t *testing.T) {
+	// Neither rule's own allow bits cover read|write on their own, but
+	// together (across two different groups) they do.
+	rules := []ruleMatch{
+		{pattern: "name", groupPerms: map[string]int32{"g1": read}},
+		{pattern: "name*", groupPerms: map[string]int32{"g2": write}},
+	}
+	operation := &acl.Operation{Code: read | write, Name: "ReadWrite"}
+
+	allow, deny, grants := computeAccess(rules, []string{"g1", "g2"}, operation)
+
+	if allow&operation.Code != operation.Code {
+		t.Fatalf("combined allow = %x, want it to cover %x", allow, operation.Code)
+	}
+	if deny != 0 {
+		t.Fatalf("deny = %x, want 0", deny)
+	}
+	if len(grants) != 2 {
+		t.Fatalf("grants = %v, want one grant per contributing group", grants)
+	}
+}
+
+func TestComputeAccessNoGrantsWhenDecisionDenied(t *testing.T) {
+	rules := []ruleMatch{
+		{pattern: "name", groupPerms: map[string]int32{"g1": read}},
+	}
+	operation := &acl.Operation{Code: read | write, Name: "ReadWrite"}
+
+	allow, _, grants := computeAccess(rules, []string{"g1"}, operation)
+
+	if allow&operation.Code == operation.Code {
+		t.Fatalf("allow = %x, should not cover %x with only Read granted", allow, operation.Code)
+	}
+	if grants != nil {
+		t.Fatalf("grants = %v, want nil when the combined decision does not allow the operation", grants)
+	}
+}
+
+func TestComputeAccessDenyOverridesAllowAcrossRules(t *testing.T) {
+	rules := []ruleMatch{
+		{pattern: "name", groupPerms: map[string]int32{"g1": read}},
+		{pattern: "name*", groupPerms: map[string]int32{"g2": read << denyShift}},
+	}
+	operation := &acl.Operation{Code: read, Name: "Read"}
+
+	_, deny, grants := computeAccess(rules, []string{"g1", "g2"}, operation)
+
+	if deny&operation.Code == 0 {
+		t.Fatalf("deny = %x, want it to cover %x", deny, operation.Code)
+	}
+	if grants != nil {
+		t.Fatalf("grants = %v, want nil when any matching rule denies the operation", grants)
+	}
+}
@@ -0,0 +1,80 @@
+// +build !oss
+
+/*
+ * Copyright 2018 Dgraph Labs, Inc. All rights reserved.
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+package edgraph
+
+// prefixTrie is a minimal byte-trie giving an O(len(predicate)) longest-
+// prefix lookup for "prefix" ACL rules (e.g. "user.*"), without pulling in
+// an external radix tree dependency: each inserted prefix costs one node
+// per byte, and a lookup walks down the trie remembering the deepest rule
+// seen along the way.
+type prefixTrie struct {
+	root *prefixTrieNode
+}
+
+type prefixTrieNode struct {
+	children map[byte]*prefixTrieNode
+	rule     *predPrefixRule
+}
+
+func newPrefixTrie() *prefixTrie {
+	return &prefixTrie{root: &prefixTrieNode{children: make(map[byte]*prefixTrieNode)}}
+}
+
+func (t *prefixTrie) insert(prefix string, rule *predPrefixRule) {
+	node := t.root
+	for i := 0; i < len(prefix); i++ {
+		b := prefix[i]
+		child, found := node.children[b]
+		if !found {
+			child = &prefixTrieNode{children: make(map[byte]*prefixTrieNode)}
+			node.children[b] = child
+		}
+		node = child
+	}
+	node.rule = rule
+}
+
+// longestPrefix returns the rule for the longest inserted prefix that
+// predicate starts with, if any.
+func (t *prefixTrie) longestPrefix(predicate string) (*predPrefixRule, bool) {
+	node := t.root
+	var best *predPrefixRule
+	for i := 0; i < len(predicate); i++ {
+		child, found := node.children[predicate[i]]
+		if !found {
+			break
+		}
+		node = child
+		if node.rule != nil {
+			best = node.rule
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return best, true
+}
+
+// walk calls fn once for every rule inserted into the trie.
+func (t *prefixTrie) walk(fn func(rule *predPrefixRule)) {
+	var visit func(node *prefixTrieNode)
+	visit = func(node *prefixTrieNode) {
+		if node.rule != nil {
+			fn(node.rule)
+		}
+		for _, child := range node.children {
+			visit(child)
+		}
+	}
+	visit(t.root)
+}
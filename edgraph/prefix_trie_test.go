@@ -0,0 +1,59 @@
+// +build !oss
+
+package edgraph
+
+import "testing"
+
+func TestPrefixTrieLongestPrefix(t *testing.T) {
+	trie := newPrefixTrie()
+	trie.insert("user.", &predPrefixRule{prefix: "user.", groupPerms: map[string]int32{"g1": 1}})
+	trie.insert("user.private.", &predPrefixRule{prefix: "user.private.", groupPerms: map[string]int32{"g2": 1}})
+
+	cases := []struct {
+		predicate  string
+		wantPrefix string
+		wantFound  bool
+	}{
+		{"user.name", "user.", true},
+		{"user.private.ssn", "user.private.", true},
+		{"friend", "", false},
+		{"user", "", false},
+	}
+	for _, c := range cases {
+		rule, found := trie.longestPrefix(c.predicate)
+		if found != c.wantFound {
+			t.Errorf("longestPrefix(%q) found = %v, want %v", c.predicate, found, c.wantFound)
+			continue
+		}
+		if found && rule.prefix != c.wantPrefix {
+			t.Errorf("longestPrefix(%q) = %q, want %q", c.predicate, rule.prefix, c.wantPrefix)
+		}
+	}
+}
+
+func TestPrefixTrieWalkVisitsEveryRule(t *testing.T) {
+	trie := newPrefixTrie()
+	trie.insert("a.", &predPrefixRule{prefix: "a."})
+	trie.insert("b.", &predPrefixRule{prefix: "b."})
+
+	seen := make(map[string]bool)
+	trie.walk(func(rule *predPrefixRule) { seen[rule.prefix] = true })
+
+	if !seen["a."] || !seen["b."] || len(seen) != 2 {
+		t.Fatalf("walk visited %v, want exactly {a., b.}", seen)
+	}
+}
+
+func TestPredWildcardRuleMatch(t *testing.T) {
+	rule := &predWildcardRule{template: "schema/+/name", segments: []string{"schema", "+", "name"}}
+
+	if !rule.match("schema/123/name") {
+		t.Error("expected schema/123/name to match schema/+/name")
+	}
+	if rule.match("schema/123/456/name") {
+		t.Error("did not expect an extra segment to match")
+	}
+	if rule.match("schema/name") {
+		t.Error("did not expect a missing segment to match")
+	}
+}
@@ -0,0 +1,45 @@
+// +build !oss
+
+/*
+ * Copyright 2018 Dgraph Labs, Inc. All rights reserved.
+ *
+ * Licensed under the Dgraph Community License (the "License"); you
+ * may not use this file except in compliance with the License. You
+ * may obtain a copy of the License at
+ *
+ *     https://github.com/dgraph-io/dgraph/blob/master/licenses/DCL.txt
+ */
+
+package acl
+
+// Group is a single ACL group as stored by the alpha: GroupID identifies it,
+// and Acls holds the JSON-encoded list of Acl rules granted to it.
+type Group struct {
+	GroupID string
+	Acls    string
+}
+
+// Acl is a single rule within a Group's Acls blob. Kind selects which index
+// edgraph's aclCache stores the rule under:
+//   - "" (the zero value) is an exact-predicate rule, matched against
+//     Predicate directly.
+//   - "prefix" is a prefix rule; Predicate carries the pattern with its
+//     trailing "*" (e.g. "user.*").
+//   - "wildcard" is a segment-wildcard rule; Predicate carries the template
+//     (e.g. "schema/+/name") with "+" matching exactly one path segment.
+// A rule with no Kind and an empty Predicate but a non-empty Regex is a
+// regex rule, matched against Regex instead.
+type Acl struct {
+	Predicate string
+	Perm      int32
+	Regex     string
+	Kind      string
+}
+
+// Operation represents a single operation being authorized, e.g. read or
+// write of a predicate. Code is a bitmask matched against the allow/deny
+// bits a group holds on a rule; Name is used in error/audit messages.
+type Operation struct {
+	Code int32
+	Name string
+}